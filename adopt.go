@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// minAdoptValueLen is the shortest environment variable value adopt will
+// consider replacing on its own; shorter values are too likely to collide
+// with unrelated text unless explicitly requested via --include.
+const minAdoptValueLen = 2
+
+// commonEnvNames lists variables whose values are near-universally present
+// in file contents by coincidence rather than by reference, so adopt skips
+// them unless explicitly included.
+var commonEnvNames = map[string]bool{
+	"PATH": true,
+}
+
+// adopt reads each file, replaces literal occurrences of the current
+// environment's values with the corresponding "{{ .env.NAME }}" action, and
+// writes the result to "<file>.template".
+func adopt(files []string, include []string, force bool) error {
+	included := map[string]bool{}
+	for _, name := range include {
+		included[name] = true
+	}
+
+	candidates := adoptCandidates(os.Environ(), included)
+
+	for _, filename := range files {
+		if err := adoptFile(filename, candidates, force); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type envCandidate struct {
+	name  string
+	value string
+}
+
+// adoptCandidates builds the ordered (longest value first) list of
+// environment variables eligible for substitution.
+func adoptCandidates(environ []string, included map[string]bool) []envCandidate {
+	var candidates []envCandidate
+
+	for _, kv := range environ {
+		name, value := splitEnv(kv)
+
+		if value == "" {
+			continue
+		}
+		if (len(value) < minAdoptValueLen || commonEnvNames[name]) && !included[name] {
+			continue
+		}
+
+		candidates = append(candidates, envCandidate{name: name, value: value})
+	}
+
+	// Longest-match-first: replace "/var/lib/app" before "/var/lib" so a
+	// shorter variable's value can't shadow part of a longer one's.
+	sort.Slice(candidates, func(i, j int) bool {
+		return len(candidates[i].value) > len(candidates[j].value)
+	})
+
+	return candidates
+}
+
+func splitEnv(kv string) (name, value string) {
+	parts := strings.SplitN(kv, "=", 2)
+	return parts[0], parts[1]
+}
+
+// adoptFile writes filename+".template" with env values replaced by
+// template actions, refusing to clobber an existing template without force.
+func adoptFile(filename string, candidates []envCandidate, force bool) error {
+	tmplname := filename + templateSuffix
+
+	if _, err := os.Stat(tmplname); err == nil && !force {
+		return fmt.Errorf("%s: already exists, use --force to overwrite", tmplname)
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	text := string(content)
+	for _, candidate := range candidates {
+		text = strings.ReplaceAll(text, candidate.value, fmt.Sprintf("{{ .env.%s }}", candidate.name))
+	}
+
+	return ioutil.WriteFile(tmplname, []byte(text), 0644)
+}
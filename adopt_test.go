@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestAdoptCandidatesOrdering(t *testing.T) {
+	environ := []string{
+		"APP_DIR=/var/lib",
+		"APP_DATA_DIR=/var/lib/app",
+		"PATH=/usr/bin",
+		"SHORT=a",
+	}
+
+	candidates := adoptCandidates(environ, nil)
+
+	if len(candidates) != 2 {
+		t.Fatalf("len(candidates) = %d, want 2 (PATH and SHORT should be excluded): %+v", len(candidates), candidates)
+	}
+	if candidates[0].name != "APP_DATA_DIR" || candidates[1].name != "APP_DIR" {
+		t.Errorf("candidates = %+v, want longest-value-first order [APP_DATA_DIR, APP_DIR]", candidates)
+	}
+}
+
+func TestAdoptCandidatesIncludeOverridesDefaults(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"SHORT=a",
+	}
+
+	candidates := adoptCandidates(environ, map[string]bool{"PATH": true, "SHORT": true})
+
+	if len(candidates) != 2 {
+		t.Fatalf("len(candidates) = %d, want 2 (both explicitly included): %+v", len(candidates), candidates)
+	}
+}
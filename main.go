@@ -2,8 +2,8 @@ package main
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/template"
 
@@ -11,17 +11,51 @@ import (
 )
 
 const usage = `Generate files and run command.
-Usage: reconf [-f -w <file> ...] [<command>...]
+Usage: reconf adopt [--include=<name> ...] [-f] <file>...
+       reconf [-f -w <file> ...] [-v <file> ...] [--set <kv> ...] [-d <dir> ...] [--copy-through] [--strict] [--watch [--reload-signal=<sig>] [--restart-on-change]] [<command>...]
 
   <command>   Command to execute. If command is not given, reconf will
               just generate files and exit.
+  adopt       Generate a "<file>.template" from each existing <file> by
+              replacing literal occurrences of the current environment's
+              values with "{{ .env.NAME }}".
 
 Options:
-  -w, --render <file>  Generate <file> (if it does not exist) by rendering
-                       template file named "<file>.template".
-                       Optional format "<template file>:<render file>" allows to be more flexible
-  -f, --force          Force generating files, overwriting existing ones.
-  -h, --help           Show this usage message and exit.
+  -w, --render <file>   Generate <file> (if it does not exist) by rendering
+                        template file named "<file>.template".
+                        Optional format "<template file>:<render file>" allows to be more flexible.
+                        <template file> may instead be a remote source with an
+                        explicit "<render file>", e.g.
+                        "https://host/tmpl:/etc/app.conf" or
+                        "git+https://host/repo.git//path/to/tmpl@ref:/etc/app.conf".
+  -d, --render-dir <dir>  Walk <dir> recursively and render every "*.template"
+                        file it finds, writing the output next to it with the
+                        ".template" suffix stripped. Optional format
+                        "<src>:<dst>" mirrors the tree under <dst> instead.
+                        May be repeated.
+  --copy-through        While rendering a directory, also copy non-template
+                        files through to the destination, preserving mode.
+  -v, --values <file>   Load values from a YAML, TOML or JSON file (detected by
+                        extension) into the template context under ".values".
+                        May be repeated; later files override earlier ones.
+  --set <kv>            Set a value override as a dotted path, e.g.
+                        "db.host=localhost". May be repeated.
+  -f, --force           Force generating files, overwriting existing ones.
+  --watch               Keep running after the initial render: re-render
+                        whenever a watched "*.template" file changes on disk
+                        or reconf receives SIGHUP, then reload <command>.
+                        SIGTERM, SIGINT, SIGUSR1 and SIGUSR2 are forwarded to
+                        <command>, and its exit code becomes reconf's own.
+  --reload-signal=<sig>  Signal sent to <command> after a watch-triggered
+                        re-render [default: HUP].
+  --restart-on-change   With --watch, restart <command> instead of signaling
+                        it after a re-render.
+  --include=<name>      With adopt, also substitute this environment variable
+                        even if its value is short or looks too common to
+                        adopt by default. May be repeated.
+  --strict              Fail the render instead of printing "<no value>" when
+                        a template references an undefined value.
+  -h, --help            Show this usage message and exit.
 `
 
 const (
@@ -32,9 +66,21 @@ const (
 )
 
 type Config struct {
-	Files   []string `docopt:"--render"`
-	Force   bool     `docopt:"--force"`
-	Command []string `docopt:"<command>"`
+	Files           []string `docopt:"--render"`
+	RenderDirs      []string `docopt:"--render-dir"`
+	CopyThrough     bool     `docopt:"--copy-through"`
+	Values          []string `docopt:"--values"`
+	Sets            []string `docopt:"--set"`
+	Force           bool     `docopt:"--force"`
+	Strict          bool     `docopt:"--strict"`
+	Watch           bool     `docopt:"--watch"`
+	ReloadSignal    string   `docopt:"--reload-signal"`
+	RestartOnChange bool     `docopt:"--restart-on-change"`
+	Command         []string `docopt:"<command>"`
+
+	Adopt      bool     `docopt:"adopt"`
+	AdoptFiles []string `docopt:"<file>"`
+	Include    []string `docopt:"--include"`
 }
 
 func main() {
@@ -59,20 +105,40 @@ func main() {
 }
 
 func run(config Config) error {
+	if config.Adopt {
+		return adopt(config.AdoptFiles, config.Include, config.Force)
+	}
+
 	envv := os.Environ()
+	values, err := loadValues(config.Values, config.Sets)
+	if err != nil {
+		return err
+	}
 	vars := map[string]interface{}{
-		"env": mapEnviron(envv),
+		"env":    mapEnviron(envv),
+		"values": values,
 	}
 
 	for _, filename := range config.Files {
 		// Leave existing file as-is (unless forced).
 		if _, err := os.Stat(filename); os.IsNotExist(err) || config.Force {
-			if err := generate(filename, vars); err != nil {
+			if err := generate(filename, vars, config.Strict); err != nil {
 				return err
 			}
 		}
 	}
 
+	for _, dir := range config.RenderDirs {
+		src, dst := dir, ""
+		if strings.Contains(dir, templateSeparator) {
+			parts := strings.SplitN(dir, templateSeparator, 2)
+			src, dst = parts[0], parts[1]
+		}
+		if err := renderDir(src, dst, vars, config.Force, config.CopyThrough, config.Strict); err != nil {
+			return err
+		}
+	}
+
 	// Just render templates and exit if command is not given.
 	if len(config.Command) == 0 {
 		return nil
@@ -82,27 +148,43 @@ func run(config Config) error {
 	// not set.
 	paths := strings.Split(os.Getenv("PATH"), ":")
 
+	if config.Watch {
+		return supervise(config, vars, templateDirs(config), paths)
+	}
+
 	return execvpe(config.Command[0], paths, config.Command, envv)
 }
 
 // Generates file by rendering corresponding template.
-func generate(filename string, vars map[string]interface{}) error {
-
-	tmplname := filename + templateSuffix
-	if strings.Contains(filename, templateSeparator) {
-		parts := strings.Split(filename, templateSeparator)
-		tmplname = parts[0]
-		filename = parts[1]
+func generate(filename string, vars map[string]interface{}, strict bool) error {
+	tmplname, dest := splitRenderArg(filename)
+	if dest == "" {
+		tmplname, dest = filename+templateSuffix, filename
 	}
+	return generatePair(tmplname, dest, vars, strict)
+}
 
+// generatePair renders tmplname into filename. Unlike generate, it takes an
+// already-separated source/destination pair and never runs it through
+// splitRenderArg's remote-source heuristic, so callers that already know
+// both paths (such as renderDir) don't risk a local path being misread as a
+// git/HTTP source spec.
+func generatePair(tmplname, filename string, vars map[string]interface{}, strict bool) error {
 	tmpl := template.New(tmplname)
 
 	// Custom functions must be set before parsing template.
-	tmpl.Funcs(templateFuncs)
+	tmpl.Funcs(buildTemplateFuncs(filepath.Dir(tmplname), vars, strict))
+
+	if strict {
+		tmpl.Option("missingkey=error")
+	}
+
+	source, err := parseTemplateSource(tmplname)
+	if err != nil {
+		return err
+	}
 
-	// ParseFiles() uses basename of the file as the name of the template. We
-	// want the path of the file as-is.
-	text, err := ioutil.ReadFile(tmplname)
+	text, err := source.Fetch()
 	if err != nil {
 		return err
 	}
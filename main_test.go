@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/docopt/docopt-go"
+)
+
+// parseArgs runs the real usage string through docopt the same way main
+// does, so a regression in Usage line ordering shows up here instead of
+// only at runtime.
+func parseArgs(t *testing.T, argv []string) Config {
+	t.Helper()
+
+	parser := docopt.Parser{
+		OptionsFirst: true,
+	}
+	opts, err := parser.ParseArgs(usage, argv, version)
+	if err != nil {
+		t.Fatalf("ParseArgs(%v): %v", argv, err)
+	}
+
+	var config Config
+	if err := opts.Bind(&config); err != nil {
+		t.Fatalf("Bind(%v): %v", argv, err)
+	}
+	return config
+}
+
+func TestParseArgsAdoptSubcommand(t *testing.T) {
+	config := parseArgs(t, []string{"adopt", "plain.conf"})
+
+	if !config.Adopt {
+		t.Errorf("Adopt = false, want true")
+	}
+	if len(config.Command) != 0 {
+		t.Errorf("Command = %v, want empty; adopt was parsed as a plain command", config.Command)
+	}
+	if want := []string{"plain.conf"}; len(config.AdoptFiles) != 1 || config.AdoptFiles[0] != want[0] {
+		t.Errorf("AdoptFiles = %v, want %v", config.AdoptFiles, want)
+	}
+}
+
+func TestParseArgsGeneralCommand(t *testing.T) {
+	config := parseArgs(t, []string{"-w", "app.conf", "myserver", "--flag"})
+
+	if config.Adopt {
+		t.Errorf("Adopt = true, want false")
+	}
+	if want := []string{"app.conf"}; len(config.Files) != 1 || config.Files[0] != want[0] {
+		t.Errorf("Files = %v, want %v", config.Files, want)
+	}
+	if want := []string{"myserver", "--flag"}; len(config.Command) != 2 || config.Command[0] != want[0] || config.Command[1] != want[1] {
+		t.Errorf("Command = %v, want %v", config.Command, want)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// renderDir walks src recursively, rendering every "*.template" file it
+// finds into the mirrored path under dst (same directory when dst is
+// empty), stripping the template suffix from the output filename. Other
+// files are left alone unless copyThrough is set, in which case they are
+// copied across mode-preserving.
+func renderDir(src, dst string, vars map[string]interface{}, force, copyThrough, strict bool) error {
+	if dst == "" {
+		dst = src
+	}
+
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if !strings.HasSuffix(path, templateSuffix) {
+			if copyThrough {
+				return copyFile(path, target, info.Mode(), force)
+			}
+			return nil
+		}
+
+		target = strings.TrimSuffix(target, templateSuffix)
+
+		if _, err := os.Stat(target); err == nil && !force {
+			return nil
+		} else if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		return generatePair(path, target, vars, strict)
+	})
+}
+
+// copyFile copies src to dst preserving mode, leaving an existing dst alone
+// unless force is set.
+func copyFile(src, dst string, mode os.FileMode, force bool) error {
+	if _, err := os.Stat(dst); err == nil && !force {
+		return nil
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(dst, data, mode)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRenderDirAtSignPath guards against a regression where renderDir routed
+// its already-separated source/dest pair through splitRenderArg, whose
+// remote-source heuristic misreads any "@" in a local path (e.g. a versioned
+// directory like "app@v2") as a git ref marker and refuses to split.
+func TestRenderDirAtSignPath(t *testing.T) {
+	src := t.TempDir()
+	srcSub := filepath.Join(src, "app@v2")
+	if err := os.MkdirAll(srcSub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcSub, "x.conf.template"), []byte("{{ .values.greeting }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	vars := map[string]interface{}{"values": map[string]interface{}{"greeting": "hi"}}
+
+	if err := renderDir(src, dst, vars, false, false, false); err != nil {
+		t.Fatalf("renderDir: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dst, "app@v2", "x.conf"))
+	if err != nil {
+		t.Fatalf("reading rendered file: %v", err)
+	}
+	if string(got) != "hi" {
+		t.Errorf("rendered content = %q, want %q", got, "hi")
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// buildTemplateFuncs returns the function map attached to every template
+// before it is parsed. baseDir is the directory of the template being
+// rendered, used to resolve "include"; vars is the same context the
+// template itself will be executed with, so an included file sees it too.
+// strict is propagated to "include" so an included template fails the same
+// way the top-level one does on an undefined value.
+func buildTemplateFuncs(baseDir string, vars map[string]interface{}, strict bool) template.FuncMap {
+	return template.FuncMap{
+		// Strings.
+		"trim":      strings.TrimSpace,
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"split":     strings.Split,
+		"join":      func(sep string, items []string) string { return strings.Join(items, sep) },
+		"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+
+		// Encoding.
+		"b64enc": func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec": templateB64Dec,
+		"toJson": templateToJSON,
+		"toYaml": templateToYAML,
+		"toToml": templateToTOML,
+
+		// Defaulting.
+		"default":  templateDefault,
+		"required": templateRequired,
+		"coalesce": templateCoalesce,
+
+		// File inclusion.
+		"include": func(path string) (string, error) { return includeTemplate(baseDir, path, vars, strict) },
+
+		// Environment.
+		"env":   func(name string) string { return os.Getenv(name) },
+		"envOr": templateEnvOr,
+	}
+}
+
+func templateB64Dec(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func templateToJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func templateToYAML(v interface{}) (string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+func templateToTOML(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// templateDefault returns val unless it is nil, an empty string or false, in
+// which case it returns def. Other zero values, such as 0 or an empty map,
+// are returned as is.
+func templateDefault(def, val interface{}) interface{} {
+	if isEmptyValue(val) {
+		return def
+	}
+	return val
+}
+
+// templateCoalesce returns the first non-empty value, or nil if all are
+// empty.
+func templateCoalesce(values ...interface{}) interface{} {
+	for _, val := range values {
+		if !isEmptyValue(val) {
+			return val
+		}
+	}
+	return nil
+}
+
+// templateRequired panics with message if val is empty; text/template's
+// executor recovers the panic and reports it as a render error annotated
+// with the template name and line number, which generate() then surfaces.
+func templateRequired(message string, val interface{}) interface{} {
+	if isEmptyValue(val) {
+		panic(message)
+	}
+	return val
+}
+
+func templateEnvOr(name, def string) string {
+	if val, ok := os.LookupEnv(name); ok {
+		return val
+	}
+	return def
+}
+
+func isEmptyValue(val interface{}) bool {
+	if val == nil {
+		return true
+	}
+	switch v := val.(type) {
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	}
+	return false
+}
+
+// includeTemplate renders the template file at path (resolved relative to
+// baseDir) with vars and returns the result, letting templates compose
+// without the caller needing to know the full filesystem layout. strict
+// carries the top-level render's --strict setting through, so an undefined
+// value referenced only inside the included file still fails the render.
+func includeTemplate(baseDir, path string, vars map[string]interface{}, strict bool) (string, error) {
+	fullpath := filepath.Join(baseDir, path)
+
+	text, err := ioutil.ReadFile(fullpath)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl := template.New(fullpath)
+	tmpl.Funcs(buildTemplateFuncs(filepath.Dir(fullpath), vars, strict))
+
+	if strict {
+		tmpl.Option("missingkey=error")
+	}
+
+	if _, err := tmpl.Parse(string(text)); err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplateDefault(t *testing.T) {
+	if got := templateDefault("fallback", ""); got != "fallback" {
+		t.Errorf("templateDefault with empty val = %v, want fallback", got)
+	}
+	if got := templateDefault("fallback", "set"); got != "set" {
+		t.Errorf("templateDefault with non-empty val = %v, want set", got)
+	}
+}
+
+func TestTemplateRequired(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("templateRequired did not panic on empty value")
+		}
+	}()
+	templateRequired("missing", "")
+}
+
+func TestTemplateRequiredPassesThrough(t *testing.T) {
+	if got := templateRequired("missing", "set"); got != "set" {
+		t.Errorf("templateRequired with non-empty val = %v, want set", got)
+	}
+}
+
+func TestTemplateCoalesce(t *testing.T) {
+	if got := templateCoalesce("", nil, "first", "second"); got != "first" {
+		t.Errorf("templateCoalesce = %v, want first", got)
+	}
+	if got := templateCoalesce("", nil, false); got != nil {
+		t.Errorf("templateCoalesce of all-empty values = %v, want nil", got)
+	}
+}
+
+// TestIncludeTemplateStrict guards against a regression where --strict only
+// set missingkey=error on the top-level template, letting an undefined
+// value referenced solely inside an included file render as "<no value>"
+// instead of failing the render.
+func TestIncludeTemplateStrict(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "inc.tmpl"), []byte("{{ .values.missingKey }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := map[string]interface{}{"values": map[string]interface{}{}}
+
+	if _, err := includeTemplate(dir, "inc.tmpl", vars, false); err != nil {
+		t.Errorf("includeTemplate non-strict = %v, want nil error", err)
+	}
+
+	if _, err := includeTemplate(dir, "inc.tmpl", vars, true); err == nil {
+		t.Errorf("includeTemplate strict = nil error, want error for undefined value")
+	}
+}
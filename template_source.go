@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// TemplateSource fetches a template's raw contents from wherever it lives —
+// the local filesystem, an HTTP(S) URL, or a git repository.
+type TemplateSource interface {
+	Fetch() ([]byte, error)
+}
+
+// fileSource reads a template from the local filesystem.
+type fileSource struct {
+	path string
+}
+
+func (s fileSource) Fetch() ([]byte, error) {
+	return ioutil.ReadFile(s.path)
+}
+
+// httpSource downloads a template from an HTTP(S) URL.
+type httpSource struct {
+	url string
+}
+
+func (s httpSource) Fetch() ([]byte, error) {
+	resp, err := http.Get(s.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status %s", s.url, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// gitSource reads a template from a subpath of a shallow clone of a git
+// repository. Clones are cached by repo+ref for the lifetime of the
+// process so a render-dir style batch of templates from the same repo
+// only clones it once.
+type gitSource struct {
+	repo    string
+	ref     string
+	subpath string
+}
+
+var gitCloneCache = map[string]string{}
+
+func (s gitSource) Fetch() ([]byte, error) {
+	dir, err := s.clone()
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(filepath.Join(dir, s.subpath))
+}
+
+func (s gitSource) clone() (string, error) {
+	key := s.repo + "@" + s.ref
+	if dir, ok := gitCloneCache[key]; ok {
+		return dir, nil
+	}
+
+	dir, err := ioutil.TempDir("", "reconf-git-")
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"clone", "--depth", "1"}
+	if s.ref != "" {
+		args = append(args, "--branch", s.ref)
+	}
+	args = append(args, s.repo, dir)
+
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git clone %s: %s", s.repo, err)
+	}
+
+	gitCloneCache[key] = dir
+	return dir, nil
+}
+
+// parseTemplateSource parses the source half of a -w/--render argument,
+// which is either a plain local path or a URI-schemed remote location such
+// as "git+https://host/repo.git//path/to/tmpl@ref" or "https://host/tmpl".
+func parseTemplateSource(spec string) (TemplateSource, error) {
+	switch {
+	case strings.HasPrefix(spec, "git+"):
+		return parseGitSource(strings.TrimPrefix(spec, "git+"))
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return httpSource{url: spec}, nil
+	default:
+		return fileSource{path: spec}, nil
+	}
+}
+
+// parseGitSource parses "<repo>//<subpath>@<ref>", where "//<subpath>" and
+// "@<ref>" are both optional, Terraform module-address style. "@" and "//"
+// occurring in HTTP userinfo (e.g. "https://user@host/...") are part of the
+// repo URL, not a ref or subpath marker, so both are only recognized past
+// the point where the repo's own path has started.
+func parseGitSource(spec string) (TemplateSource, error) {
+	repo, ref := spec, ""
+	if i := strings.LastIndex(repo, "@"); i > strings.LastIndex(repo, "/") {
+		repo, ref = repo[:i], repo[i+1:]
+	}
+
+	schemeEnd := 0
+	if i := strings.Index(repo, "://"); i >= 0 {
+		schemeEnd = i + len("://")
+	}
+
+	subpath := ""
+	if i := strings.Index(repo[schemeEnd:], "//"); i >= 0 {
+		i += schemeEnd
+		repo, subpath = repo[:i], repo[i+2:]
+	}
+
+	if repo == "" {
+		return nil, fmt.Errorf("%s: missing git repository", spec)
+	}
+
+	return gitSource{repo: repo, ref: ref, subpath: subpath}, nil
+}
+
+// splitRenderArg splits a -w/--render argument into its source spec and
+// destination path. The separator is the last ":" whose remainder looks
+// like a plain local path rather than part of a remote source spec — i.e.
+// it contains neither "//" (a scheme or module-subpath marker) nor "@" (git
+// ref or HTTP userinfo separator). No such colon means no explicit
+// destination.
+func splitRenderArg(arg string) (source, dest string) {
+	for i := len(arg) - 1; i >= 0; i-- {
+		if arg[i] != ':' {
+			continue
+		}
+		remainder := arg[i+1:]
+		if strings.Contains(remainder, "//") || strings.Contains(remainder, "@") {
+			continue
+		}
+		return arg[:i], remainder
+	}
+	return arg, ""
+}
@@ -0,0 +1,119 @@
+package main
+
+import "testing"
+
+func TestSplitRenderArg(t *testing.T) {
+	tests := []struct {
+		name       string
+		arg        string
+		wantSource string
+		wantDest   string
+	}{
+		{
+			name:       "plain local path, no dest",
+			arg:        "app.conf",
+			wantSource: "app.conf",
+			wantDest:   "",
+		},
+		{
+			name:       "local source and dest",
+			arg:        "app.conf.template:app.conf",
+			wantSource: "app.conf.template",
+			wantDest:   "app.conf",
+		},
+		{
+			name:       "http source with explicit dest",
+			arg:        "https://host/tmpl:/etc/app.conf",
+			wantSource: "https://host/tmpl",
+			wantDest:   "/etc/app.conf",
+		},
+		{
+			name:       "git source with ref and explicit dest",
+			arg:        "git+https://host/repo.git//path/to/tmpl@ref:/etc/app.conf",
+			wantSource: "git+https://host/repo.git//path/to/tmpl@ref",
+			wantDest:   "/etc/app.conf",
+		},
+		{
+			name:       "http source with userinfo and no dest",
+			arg:        "https://user@host/tmpl",
+			wantSource: "https://user@host/tmpl",
+			wantDest:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, dest := splitRenderArg(tt.arg)
+			if source != tt.wantSource || dest != tt.wantDest {
+				t.Errorf("splitRenderArg(%q) = (%q, %q), want (%q, %q)",
+					tt.arg, source, dest, tt.wantSource, tt.wantDest)
+			}
+		})
+	}
+}
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        string
+		wantRepo    string
+		wantRef     string
+		wantSubpath string
+		wantErr     bool
+	}{
+		{
+			name:     "bare repo",
+			spec:     "https://host/repo.git",
+			wantRepo: "https://host/repo.git",
+		},
+		{
+			name:        "repo with subpath",
+			spec:        "https://host/repo.git//path/to/tmpl",
+			wantRepo:    "https://host/repo.git",
+			wantSubpath: "path/to/tmpl",
+		},
+		{
+			name:     "repo with ref",
+			spec:     "https://host/repo.git@v1.2.3",
+			wantRepo: "https://host/repo.git",
+			wantRef:  "v1.2.3",
+		},
+		{
+			name:        "repo with subpath and ref",
+			spec:        "https://host/repo.git//path/to/tmpl@ref",
+			wantRepo:    "https://host/repo.git",
+			wantSubpath: "path/to/tmpl",
+			wantRef:     "ref",
+		},
+		{
+			name:     "userinfo @ is not a ref marker",
+			spec:     "https://user@host/repo.git",
+			wantRepo: "https://user@host/repo.git",
+		},
+		{
+			name:    "empty spec",
+			spec:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := parseGitSource(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseGitSource(%q) = nil error, want error", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseGitSource(%q) = %v", tt.spec, err)
+			}
+			got := source.(gitSource)
+			if got.repo != tt.wantRepo || got.ref != tt.wantRef || got.subpath != tt.wantSubpath {
+				t.Errorf("parseGitSource(%q) = %+v, want {repo:%q ref:%q subpath:%q}",
+					tt.spec, got, tt.wantRepo, tt.wantRef, tt.wantSubpath)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// loadValues reads each values file in order, merging later files over
+// earlier ones, then applies --set overrides on top of the result.
+func loadValues(files []string, sets []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for _, filename := range files {
+		parsed, err := loadValuesFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		values = mergeValues(values, parsed)
+	}
+
+	for _, set := range sets {
+		if err := applySet(values, set); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// loadValuesFile parses a single values file, picking the decoder from its
+// extension.
+func loadValuesFile(filename string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unrecognized values file extension %q", filename, ext)
+	}
+
+	return normalizeValues(values), nil
+}
+
+// normalizeValues walks a decoded values tree, converting the
+// map[interface{}]interface{} produced by the YAML decoder into
+// map[string]interface{} so templates can index it uniformly.
+func normalizeValues(value interface{}) map[string]interface{} {
+	normalized, _ := normalize(value).(map[string]interface{})
+	return normalized
+}
+
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprintf("%v", k)] = normalize(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = normalize(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = normalize(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// mergeValues deep-merges src into dst, with src winning on conflicts, and
+// returns the merged map.
+func mergeValues(dst, src map[string]interface{}) map[string]interface{} {
+	for key, value := range src {
+		if existing, ok := dst[key]; ok {
+			existingMap, existingIsMap := existing.(map[string]interface{})
+			valueMap, valueIsMap := value.(map[string]interface{})
+			if existingIsMap && valueIsMap {
+				dst[key] = mergeValues(existingMap, valueMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+// applySet parses a Helm-style "dotted.path=value" override and sets it in
+// values, creating any intermediate maps as needed.
+func applySet(values map[string]interface{}, set string) error {
+	parts := strings.SplitN(set, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("--set %q: expected key=value", set)
+	}
+	path, value := strings.Split(parts[0], "."), parts[1]
+
+	node := values
+	for _, key := range path[:len(path)-1] {
+		next, ok := node[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			node[key] = next
+		}
+		node = next
+	}
+	node[path[len(path)-1]] = value
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeValues(t *testing.T) {
+	dst := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"port": "5432",
+		},
+		"keep": "dst",
+	}
+	src := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "remote",
+		},
+		"extra": "src",
+	}
+
+	got := mergeValues(dst, src)
+
+	want := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "remote",
+			"port": "5432",
+		},
+		"keep":  "dst",
+		"extra": "src",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeValues = %#v, want %#v", got, want)
+	}
+}
+
+func TestMergeValuesNonMapOverwritesMap(t *testing.T) {
+	dst := map[string]interface{}{
+		"db": map[string]interface{}{"host": "localhost"},
+	}
+	src := map[string]interface{}{
+		"db": "not-a-map",
+	}
+
+	got := mergeValues(dst, src)
+
+	if got["db"] != "not-a-map" {
+		t.Errorf("mergeValues[\"db\"] = %#v, want \"not-a-map\"", got["db"])
+	}
+}
+
+func TestApplySet(t *testing.T) {
+	values := map[string]interface{}{}
+
+	if err := applySet(values, "db.host=localhost"); err != nil {
+		t.Fatalf("applySet: %v", err)
+	}
+	if err := applySet(values, "db.port=5432"); err != nil {
+		t.Fatalf("applySet: %v", err)
+	}
+	if err := applySet(values, "name=app"); err != nil {
+		t.Fatalf("applySet: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"port": "5432",
+		},
+		"name": "app",
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("values after applySet = %#v, want %#v", values, want)
+	}
+}
+
+func TestApplySetInvalid(t *testing.T) {
+	values := map[string]interface{}{}
+	if err := applySet(values, "no-equals-sign"); err == nil {
+		t.Errorf("applySet(%q) = nil error, want error", "no-equals-sign")
+	}
+}
+
+func TestLoadValuesFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+	}{
+		{"yaml", ".yaml", "db:\n  host: localhost\n"},
+		{"json", ".json", `{"db": {"host": "localhost"}}`},
+		{"toml", ".toml", "[db]\nhost = \"localhost\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "values"+tt.ext)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			values, err := loadValuesFile(path)
+			if err != nil {
+				t.Fatalf("loadValuesFile: %v", err)
+			}
+
+			db, ok := values["db"].(map[string]interface{})
+			if !ok || db["host"] != "localhost" {
+				t.Errorf("loadValuesFile(%s) = %#v, want db.host = localhost", tt.ext, values)
+			}
+		})
+	}
+}
+
+func TestLoadValuesFileUnrecognizedExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.ini")
+	if err := os.WriteFile(path, []byte("x=1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := loadValuesFile(path); err == nil {
+		t.Errorf("loadValuesFile(.ini) = nil error, want error")
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// templateDirs collects the distinct directories fsnotify should watch:
+// the parent of every "-w" template and every "-d" source tree.
+func templateDirs(config Config) []string {
+	seen := map[string]bool{}
+	var dirs []string
+
+	add := func(dir string) {
+		if dir == "" {
+			dir = "."
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for _, filename := range config.Files {
+		tmplname, dest := splitRenderArg(filename)
+		if dest == "" {
+			tmplname = filename + templateSuffix
+		}
+		// Remote (http/git) sources have nothing on the local filesystem to
+		// watch; only local files can trigger a re-render via fsnotify.
+		if source, err := parseTemplateSource(tmplname); err == nil {
+			if _, ok := source.(fileSource); ok {
+				add(filepath.Dir(tmplname))
+			}
+		}
+	}
+
+	for _, dir := range config.RenderDirs {
+		src := dir
+		if strings.Contains(dir, templateSeparator) {
+			src = strings.SplitN(dir, templateSeparator, 2)[0]
+		}
+		filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.IsDir() {
+				add(path)
+			}
+			return nil
+		})
+	}
+
+	return dirs
+}
+
+// signalByName resolves a signal name such as "HUP" or "SIGHUP" to its
+// syscall.Signal value.
+func signalByName(name string) (syscall.Signal, error) {
+	switch strings.ToUpper(strings.TrimPrefix(name, "SIG")) {
+	case "HUP":
+		return syscall.SIGHUP, nil
+	case "USR1":
+		return syscall.SIGUSR1, nil
+	case "USR2":
+		return syscall.SIGUSR2, nil
+	case "TERM":
+		return syscall.SIGTERM, nil
+	case "INT":
+		return syscall.SIGINT, nil
+	default:
+		return 0, fmt.Errorf("unknown signal %q", name)
+	}
+}
+
+// supervise renders templates once, then starts command as a child process
+// and keeps it running: template changes and SIGHUP trigger a re-render
+// followed by either a reload signal to the child or a full restart, and
+// SIGTERM, SIGINT, SIGUSR1 and SIGUSR2 are forwarded to the child as-is. The
+// child's exit code becomes reconf's exit code.
+func supervise(config Config, vars map[string]interface{}, templateDirs []string, paths []string) error {
+	reloadSignal, err := signalByName(config.ReloadSignal)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	for _, dir := range templateDirs {
+		if err := watcher.Add(dir); err != nil {
+			return err
+		}
+	}
+
+	cmd, err := startChild(config.Command, paths)
+	if err != nil {
+		return err
+	}
+
+	// Only forward the signals reconf itself understands (the ones
+	// signalByName can resolve); the catch-all form of Notify also relays
+	// signals like SIGURG (sent to threads for goroutine preemption) and
+	// SIGCHLD/SIGWINCH/SIGPIPE, which the supervised child should never see.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGHUP)
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	for {
+		select {
+		case event := <-watcher.Events:
+			if !strings.HasSuffix(event.Name, templateSuffix) {
+				continue
+			}
+			if err := reRender(config, vars); err != nil {
+				fmt.Fprintln(os.Stderr, "error:", err)
+				continue
+			}
+			if config.RestartOnChange {
+				cmd, exited, err = restartChild(cmd, exited, config.Command, paths)
+				if err != nil {
+					return err
+				}
+			} else {
+				cmd.Process.Signal(reloadSignal)
+			}
+
+		case err := <-watcher.Errors:
+			fmt.Fprintln(os.Stderr, "watch error:", err)
+
+		case sig := <-sigCh:
+			if sig == syscall.SIGHUP {
+				if err := reRender(config, vars); err != nil {
+					fmt.Fprintln(os.Stderr, "error:", err)
+					continue
+				}
+				if config.RestartOnChange {
+					cmd, exited, err = restartChild(cmd, exited, config.Command, paths)
+					if err != nil {
+						return err
+					}
+					continue
+				}
+				cmd.Process.Signal(reloadSignal)
+				continue
+			}
+			cmd.Process.Signal(sig)
+
+		case err := <-exited:
+			return childExitError(err)
+		}
+	}
+}
+
+// reRender re-runs every file and directory render. Unlike the initial
+// render, it always overwrites the destination regardless of --force: the
+// initial render only fills in files that don't exist yet, but a
+// watch-triggered reload is specifically about picking up a template change,
+// so every -w file is regenerated unconditionally and every -d tree is
+// rendered as if --force had been passed.
+func reRender(config Config, vars map[string]interface{}) error {
+	for _, filename := range config.Files {
+		if err := generate(filename, vars, config.Strict); err != nil {
+			return err
+		}
+	}
+	for _, dir := range config.RenderDirs {
+		src, dst := dir, ""
+		if strings.Contains(dir, templateSeparator) {
+			parts := strings.SplitN(dir, templateSeparator, 2)
+			src, dst = parts[0], parts[1]
+		}
+		if err := renderDir(src, dst, vars, true, config.CopyThrough, config.Strict); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startChild(command []string, paths []string) (*exec.Cmd, error) {
+	path, err := lookupPath(command[0], paths)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(path, command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// restartChild stops the current child and starts a fresh one in its place.
+// It waits for the old process on exited, the channel the caller's own
+// background goroutine is already blocked delivering to, rather than
+// calling cmd.Wait() a second time: exec.Cmd.Wait is documented as invalid
+// to call more than once and races on the same *exec.Cmd otherwise.
+func restartChild(cmd *exec.Cmd, exited chan error, command []string, paths []string) (*exec.Cmd, chan error, error) {
+	cmd.Process.Signal(syscall.SIGTERM)
+	<-exited
+
+	newCmd, err := startChild(command, paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newExited := make(chan error, 1)
+	go func() { newExited <- newCmd.Wait() }()
+	return newCmd, newExited, nil
+}
+
+// lookupPath resolves command against paths the same way execvpe does.
+func lookupPath(command string, paths []string) (string, error) {
+	if strings.Contains(command, "/") {
+		return command, nil
+	}
+	for _, dir := range paths {
+		candidate := filepath.Join(dir, command)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%s: command not found", command)
+}
+
+// childExitError turns the error from (*exec.Cmd).Wait into reconf's own
+// exit, propagating the child's exit code via os.Exit.
+func childExitError(err error) error {
+	if err == nil {
+		os.Exit(0)
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			os.Exit(status.ExitStatus())
+		}
+	}
+	return err
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestSignalByName(t *testing.T) {
+	tests := []struct {
+		name string
+		want syscall.Signal
+	}{
+		{"HUP", syscall.SIGHUP},
+		{"SIGHUP", syscall.SIGHUP},
+		{"usr1", syscall.SIGUSR1},
+		{"USR2", syscall.SIGUSR2},
+		{"TERM", syscall.SIGTERM},
+		{"INT", syscall.SIGINT},
+	}
+
+	for _, tt := range tests {
+		got, err := signalByName(tt.name)
+		if err != nil {
+			t.Errorf("signalByName(%q) returned error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("signalByName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSignalByNameUnknown(t *testing.T) {
+	if _, err := signalByName("KILL"); err == nil {
+		t.Errorf("signalByName(%q) = nil error, want error", "KILL")
+	}
+}